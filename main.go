@@ -2,15 +2,32 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"flag"
 	"fmt"
+	"html"
+	"html/template"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"regexp"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/BurntSushi/toml"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"go.abhg.dev/goldmark/toc"
 	"gopkg.in/yaml.v3"
 )
 
@@ -19,6 +36,9 @@ type frontMatter struct {
 	slug       string
 	categories []string
 	postType   string
+	toc        bool
+	autonumber bool
+	showTags   bool
 }
 
 type blogPost struct {
@@ -26,6 +46,10 @@ type blogPost struct {
 	dateStr     string
 	excerpt     string
 	body        string
+	readingTime int
+	toc         []tocHeading
+	created     time.Time
+	updated     time.Time
 }
 
 func parseBlogPost(text string) (blogPost, error) {
@@ -47,6 +71,9 @@ func parseBlogPost(text string) (blogPost, error) {
 		Categories       string   `yaml:"categories"`
 		Tag              []string `yaml:"tag"`
 		Type             string   `yaml:"type"`
+		TOC              bool     `yaml:"toc"`
+		AutoNumber       bool     `yaml:"autonumber"`
+		ShowTags         bool     `yaml:"showTags"`
 	}
 	var yfm YamlFrontMatter
 	err := yaml.Unmarshal([]byte(frontMatterStr), &yfm)
@@ -66,6 +93,9 @@ func parseBlogPost(text string) (blogPost, error) {
 	} else {
 		fm.postType = "note"
 	}
+	fm.toc = yfm.TOC
+	fm.autonumber = yfm.AutoNumber
+	fm.showTags = yfm.ShowTags
 	bp.frontMatter = fm
 
 	// Extract excerpt and rest of body
@@ -83,7 +113,7 @@ func parseBlogPost(text string) (blogPost, error) {
 	return bp, nil
 }
 
-func process(filePath string) (blogPost, error) {
+func process(repoPath, filePath string, datesCache vcsDatesCache, datesMu *sync.Mutex) (blogPost, error) {
 	bp := blogPost{}
 	// extract date from filepath
 	re := regexp.MustCompile(`^.*/(\d{4}-\d{2}-\d{2}).*$`)
@@ -108,20 +138,207 @@ func process(filePath string) (blogPost, error) {
 		return bp, fmt.Errorf("on parse blog post (%s): %w", filePath, err)
 	}
 	bp.dateStr = dateStr
-	return bp, err
+
+	excerpt, err := renderExcerpt(bp.excerpt)
+	if err != nil {
+		return bp, fmt.Errorf("on render excerpt (%s): %w", filePath, err)
+	}
+	bp.excerpt = excerpt
+	bp.readingTime = calcReadingTime(bp.body)
+
+	if bp.frontMatter.toc {
+		bp.toc, err = buildTOC(bp.body)
+		if err != nil {
+			return bp, fmt.Errorf("on build toc (%s): %w", filePath, err)
+		}
+	}
+
+	fallback, err := time.Parse("2006-01-02", dateStr)
+	if err != nil {
+		return bp, fmt.Errorf("unable to parse fallback date %q: %w", dateStr, err)
+	}
+	bp.created, bp.updated = getPostDates(repoPath, filePath, datesCache, datesMu, fallback)
+	return bp, nil
+}
+
+// calcReadingTime estimates reading time in minutes at ~220 words per
+// minute, excluding fenced code blocks from the word count.
+func calcReadingTime(body string) int {
+	const wordsPerMinute = 220
+	withoutCode := regexp.MustCompile("(?s)```.*?```|~~~.*?~~~").ReplaceAllString(body, "")
+	words := strings.Fields(withoutCode)
+	minutes := (len(words) + wordsPerMinute - 1) / wordsPerMinute
+	if minutes < 1 {
+		minutes = 1
+	}
+	return minutes
+}
+
+// tocHeading is one node of the heading tree derived from a post's body,
+// serialized as a nested TOML array under the HugoFrontMatter "toc" key.
+type tocHeading struct {
+	Title    string       `toml:"title"`
+	Level    int          `toml:"level"`
+	Anchor   string       `toml:"anchor"`
+	Children []tocHeading `toml:"children,omitempty"`
+}
+
+// buildTOC parses body with goldmark and goldmark-toc to derive its heading
+// tree.
+func buildTOC(body string) ([]tocHeading, error) {
+	source := []byte(body)
+	doc := markdownRenderer.Parser().Parse(text.NewReader(source))
+	tree, err := toc.Inspect(doc, source)
+	if err != nil {
+		return nil, fmt.Errorf("unable to build toc: %w", err)
+	}
+	return tocItemsToHeadings(tree.Items, 1), nil
+}
+
+func tocItemsToHeadings(items []*toc.Item, level int) []tocHeading {
+	headings := make([]tocHeading, 0, len(items))
+	for _, item := range items {
+		headings = append(headings, tocHeading{
+			Title:    string(item.Title),
+			Level:    level,
+			Anchor:   string(item.ID),
+			Children: tocItemsToHeadings(item.Items, level+1),
+		})
+	}
+	return headings
+}
+
+// vcsDatesEntry is a single cached record in the dates sidecar, keyed by
+// post path. ModTime lets subsequent runs detect whether the file changed
+// since it was cached, without having to re-walk the git history.
+type vcsDatesEntry struct {
+	ModTime time.Time `json:"modTime"`
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+}
+
+type vcsDatesCache map[string]vcsDatesEntry
+
+func loadVCSDatesCache(path string) (vcsDatesCache, error) {
+	cache := vcsDatesCache{}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read dates cache %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, fmt.Errorf("unable to parse dates cache %s: %w", path, err)
+	}
+	return cache, nil
+}
+
+func saveVCSDatesCache(path string, cache vcsDatesCache) error {
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to marshal dates cache: %w", err)
+	}
+	return os.WriteFile(path, data, os.ModePerm)
+}
+
+// getPostDates returns the created/updated timestamps for filePath, backed
+// by getPostVCSDates and datesCache. It falls back to fallback (typically
+// the date parsed out of the filename) when the file is untracked or its
+// enclosing repo can't be opened. datesMu guards datesCache so this is safe
+// to call concurrently from a worker pool.
+func getPostDates(repoPath, filePath string, datesCache vcsDatesCache, datesMu *sync.Mutex, fallback time.Time) (created, updated time.Time) {
+	info, statErr := os.Stat(filePath)
+	if statErr == nil {
+		datesMu.Lock()
+		entry, ok := datesCache[filePath]
+		datesMu.Unlock()
+		if ok && entry.ModTime.Equal(info.ModTime()) {
+			return entry.Created, entry.Updated
+		}
+	}
+
+	created, updated, err := getPostVCSDates(repoPath, filePath)
+	if err != nil {
+		return fallback, fallback
+	}
+
+	if statErr == nil {
+		datesMu.Lock()
+		datesCache[filePath] = vcsDatesEntry{ModTime: info.ModTime(), Created: created, Updated: updated}
+		datesMu.Unlock()
+	}
+	return created, updated
+}
+
+// getPostVCSDates opens the git repo enclosing repoPath and walks the
+// commit history touching filePath, returning the committer time of the
+// oldest commit (created) and the most recent commit (updated).
+func getPostVCSDates(repoPath, filePath string) (created, updated time.Time, err error) {
+	absRepoPath, err := filepath.Abs(repoPath)
+	if err != nil {
+		return created, updated, fmt.Errorf("unable to resolve absolute path for %s: %w", repoPath, err)
+	}
+	repo, err := git.PlainOpenWithOptions(absRepoPath, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return created, updated, fmt.Errorf("unable to open repo at %s: %w", repoPath, err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		return created, updated, fmt.Errorf("unable to get worktree for %s: %w", repoPath, err)
+	}
+	absFilePath, err := filepath.Abs(filePath)
+	if err != nil {
+		return created, updated, fmt.Errorf("unable to resolve absolute path for %s: %w", filePath, err)
+	}
+	rel, err := filepath.Rel(wt.Filesystem.Root(), absFilePath)
+	if err != nil {
+		return created, updated, fmt.Errorf("unable to compute path for %s relative to repo: %w", filePath, err)
+	}
+	rel = filepath.ToSlash(rel)
+
+	head, err := repo.Head()
+	if err != nil {
+		return created, updated, fmt.Errorf("unable to resolve HEAD: %w", err)
+	}
+	commits, err := repo.Log(&git.LogOptions{From: head.Hash(), FileName: &rel})
+	if err != nil {
+		return created, updated, fmt.Errorf("unable to walk history for %s: %w", rel, err)
+	}
+	defer commits.Close()
+
+	err = commits.ForEach(func(c *object.Commit) error {
+		t := c.Committer.When
+		if created.IsZero() || t.Before(created) {
+			created = t
+		}
+		if updated.IsZero() || t.After(updated) {
+			updated = t
+		}
+		return nil
+	})
+	if err != nil {
+		return created, updated, fmt.Errorf("error walking commits for %s: %w", rel, err)
+	}
+	if created.IsZero() {
+		return created, updated, fmt.Errorf("no commits found touching %s", rel)
+	}
+	return created, updated, nil
 }
 
 type HugoFrontMatter struct {
-	Title      string   `toml:"title"`
-	Date       string   `toml:"date"`
-	Summary    string   `toml:"summary"`
-	Tags       []string `toml:"tags"`
-	Type       string   `toml:"type"`
-	TOC        bool     `toml:"toc"`
-	ReadTime   bool     `toml:"readTime"`
-	AutoNumber bool     `toml:"autonumber"`
-	ShowTags   bool     `toml:"showTags"`
-	Slug       string   `toml:"slug"`
+	Title       string       `toml:"title"`
+	Date        string       `toml:"date"`
+	LastMod     string       `toml:"lastmod"`
+	Summary     string       `toml:"summary"`
+	Tags        []string     `toml:"tags"`
+	Type        string       `toml:"type"`
+	TOC         []tocHeading `toml:"toc,omitempty"`
+	ReadTime    bool         `toml:"readTime"`
+	ReadingTime int          `toml:"readingTime"`
+	AutoNumber  bool         `toml:"autonumber"`
+	ShowTags    bool         `toml:"showTags"`
+	Slug        string       `toml:"slug"`
 }
 
 func NewHugoFrontMatter(title string) *HugoFrontMatter {
@@ -136,13 +353,18 @@ func NewHugoFrontMatter(title string) *HugoFrontMatter {
 func (bp *blogPost) generateHugoFrontmatter() *HugoFrontMatter {
 	fm := bp.frontMatter
 	hfm := &HugoFrontMatter{
-		Title:    fm.title,
-		Slug:     fm.slug,
-		Date:     bp.dateStr,
-		Summary:  bp.excerpt,
-		Tags:     fm.categories,
-		Type:     fm.postType,
-		ReadTime: true,
+		Title:       fm.title,
+		Slug:        fm.slug,
+		Date:        bp.created.Format("2006-01-02"),
+		LastMod:     bp.updated.Format("2006-01-02"),
+		Summary:     bp.excerpt,
+		Tags:        fm.categories,
+		Type:        fm.postType,
+		TOC:         bp.toc,
+		ReadTime:    true,
+		ReadingTime: bp.readingTime,
+		AutoNumber:  fm.autonumber,
+		ShowTags:    fm.showTags,
 	}
 	return hfm
 }
@@ -172,11 +394,25 @@ func getBlogDirname(filename string) (string, error) {
 	return name, nil
 }
 
-func writeOutBlogPost(dir string, currFilename string, bp *blogPost) error {
+// claimedOutputDirs tracks which source filename has already claimed a given
+// output directory, so that two posts whose sanitized dirnames collide (e.g.
+// "dup-a-b" and "dup_a_b" both becoming "dup_a_b") are reported as an error
+// instead of one silently overwriting the other's index file. claimedMu
+// guards it the same way datesMu guards vcsDatesCache.
+type claimedOutputDirs map[string]string
+
+func writeOutBlogPost(dir string, currFilename string, bp *blogPost, renderMode bool, claimed claimedOutputDirs, claimedMu *sync.Mutex) error {
 	var content bytes.Buffer
-	err := getBlogContent(bp, &content)
-	if err != nil {
-		return err
+	outFilename := "index.md"
+	if renderMode {
+		outFilename = "index.html"
+		if err := renderPostPage(bp, &content); err != nil {
+			return err
+		}
+	} else {
+		if err := getBlogContent(bp, &content); err != nil {
+			return err
+		}
 	}
 	blogDirname, err := getBlogDirname(currFilename)
 	if err != nil {
@@ -187,11 +423,22 @@ func writeOutBlogPost(dir string, currFilename string, bp *blogPost) error {
 		subDir = "posts"
 	}
 	blogPostDirpath := filepath.Join(dir, subDir, blogDirname)
-	err = os.Mkdir(blogPostDirpath, os.ModePerm)
+
+	claimedMu.Lock()
+	owner, taken := claimed[blogPostDirpath]
+	if !taken {
+		claimed[blogPostDirpath] = currFilename
+	}
+	claimedMu.Unlock()
+	if taken && owner != currFilename {
+		return fmt.Errorf("output directory %s for %s already claimed by %s", blogPostDirpath, currFilename, owner)
+	}
+
+	err = os.MkdirAll(blogPostDirpath, os.ModePerm)
 	if err != nil {
 		return err
 	}
-	outFilePath := filepath.Join(blogPostDirpath, "index.md")
+	outFilePath := filepath.Join(blogPostDirpath, outFilename)
 	file, err := os.Create(outFilePath)
 	if err != nil {
 		return err
@@ -207,24 +454,477 @@ func writeOutBlogPost(dir string, currFilename string, bp *blogPost) error {
 	return errOnClose
 }
 
+// markdownRenderer is the shared goldmark instance used both to render post
+// bodies to HTML and, via buildTOC, to derive their table of contents, so
+// the heading ids WithAutoHeadingID assigns line up with the anchors in
+// tocHeading.Anchor.
+var markdownRenderer = goldmark.New(
+	goldmark.WithExtensions(extension.GFM, extension.Footnote, extension.Typographer),
+	goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+)
+
+// renderPost converts bp.body Markdown to HTML using markdownRenderer.
+func renderPost(bp *blogPost) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := markdownRenderer.Convert([]byte(bp.body), &buf); err != nil {
+		return nil, fmt.Errorf("unable to render post body: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// renderExcerpt renders excerptMarkdown to HTML and strips the tags back
+// out, so the resulting plain-text excerpt is consistent whether it ends up
+// in Hugo frontmatter or a rendered HTML page.
+func renderExcerpt(excerptMarkdown string) (string, error) {
+	md := goldmark.New(goldmark.WithExtensions(extension.GFM, extension.Typographer))
+	var buf bytes.Buffer
+	if err := md.Convert([]byte(excerptMarkdown), &buf); err != nil {
+		return "", fmt.Errorf("unable to render excerpt: %w", err)
+	}
+	return html.UnescapeString(stripHTMLTags(buf.String())), nil
+}
+
+func stripHTMLTags(html string) string {
+	stripped := regexp.MustCompile(`<[^>]*>`).ReplaceAllString(html, "")
+	return strings.TrimSpace(regexp.MustCompile(`\s+`).ReplaceAllString(stripped, " "))
+}
+
+const postPageLayout = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<meta name="description" content="{{.Excerpt}}">
+</head>
+<body>
+<article>
+<h1>{{.Title}}</h1>
+<p class="reading-time">{{.ReadingTime}} min read</p>
+{{if .TOC}}<nav class="toc">{{.TOC}}</nav>{{end}}
+{{.Content}}
+</article>
+</body>
+</html>
+`
+
+type postPage struct {
+	Title       string
+	Excerpt     string
+	Content     template.HTML
+	TOC         template.HTML
+	ReadingTime int
+}
+
+// renderTOC renders headings as nested <ul> lists of anchor links into the
+// heading ids markdownRenderer assigns, mirroring the tree written to Hugo
+// frontmatter by generateHugoFrontmatter.
+func renderTOC(headings []tocHeading) template.HTML {
+	if len(headings) == 0 {
+		return ""
+	}
+	var buf bytes.Buffer
+	buf.WriteString("<ul>")
+	for _, h := range headings {
+		fmt.Fprintf(&buf, `<li><a href="#%s">%s</a>`, h.Anchor, template.HTMLEscapeString(h.Title))
+		buf.WriteString(string(renderTOC(h.Children)))
+		buf.WriteString("</li>")
+	}
+	buf.WriteString("</ul>")
+	return template.HTML(buf.String())
+}
+
+// renderPostPage renders bp to a full HTML document, wrapping the
+// goldmark-rendered body in postPageLayout, and writes it into out.
+func renderPostPage(bp *blogPost, out *bytes.Buffer) error {
+	contentHTML, err := renderPost(bp)
+	if err != nil {
+		return err
+	}
+	tmpl, err := template.New("post").Parse(postPageLayout)
+	if err != nil {
+		return fmt.Errorf("unable to parse post page layout: %w", err)
+	}
+	page := postPage{
+		Title:       bp.frontMatter.title,
+		Excerpt:     bp.excerpt,
+		Content:     template.HTML(contentHTML),
+		TOC:         renderTOC(bp.toc),
+		ReadingTime: bp.readingTime,
+	}
+	if err := tmpl.Execute(out, page); err != nil {
+		return fmt.Errorf("unable to render post page: %w", err)
+	}
+	return nil
+}
+
+// feedConfig configures the Atom/RSS feeds written by writeFeeds. It is
+// loaded from a small TOML file kept alongside _posts.
+type feedConfig struct {
+	Title   string `toml:"title"`
+	BaseURL string `toml:"base_url"`
+	Author  string `toml:"author"`
+	Limit   int    `toml:"limit"`
+}
+
+func loadFeedConfig(path string) (feedConfig, error) {
+	var cfg feedConfig
+	if _, err := toml.DecodeFile(path, &cfg); err != nil {
+		return cfg, fmt.Errorf("unable to load feed config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+func postPermalink(baseURL, slug string) string {
+	return strings.TrimRight(baseURL, "/") + "/posts/" + slug + "/"
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",cdata"`
+}
+
+type atomEntry struct {
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Link    atomLink    `xml:"link"`
+	Summary string      `xml:"summary"`
+	Content atomContent `xml:"content"`
+}
+
+type atomFeed struct {
+	XMLName xml.Name   `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string     `xml:"title"`
+	ID      string     `xml:"id"`
+	Updated string     `xml:"updated"`
+	Links   []atomLink `xml:"link"`
+	Author  struct {
+		Name string `xml:"name"`
+	} `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type rssItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	GUID        string `xml:"guid"`
+	PubDate     string `xml:"pubDate"`
+	Description string `xml:"description"`
+}
+
+type rssChannel struct {
+	Title       string    `xml:"title"`
+	Link        string    `xml:"link"`
+	Description string    `xml:"description"`
+	Items       []rssItem `xml:"item"`
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+// writeFeeds renders posts (already filtered to postType=="post") into
+// content/atom.xml and content/rss.xml, sorted by date descending and
+// capped at cfg.Limit when set.
+func writeFeeds(outDir string, posts []blogPost, cfg feedConfig) error {
+	sorted := make([]blogPost, len(posts))
+	copy(sorted, posts)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].dateStr != sorted[j].dateStr {
+			return sorted[i].dateStr > sorted[j].dateStr
+		}
+		return sorted[i].frontMatter.slug < sorted[j].frontMatter.slug
+	})
+	if cfg.Limit > 0 && len(sorted) > cfg.Limit {
+		sorted = sorted[:cfg.Limit]
+	}
+
+	atomDoc, err := buildAtomFeed(sorted, cfg)
+	if err != nil {
+		return err
+	}
+	if err := writeXMLFile(filepath.Join(outDir, "atom.xml"), atomDoc); err != nil {
+		return err
+	}
+
+	rssDoc, err := buildRSSFeed(sorted, cfg)
+	if err != nil {
+		return err
+	}
+	return writeXMLFile(filepath.Join(outDir, "rss.xml"), rssDoc)
+}
+
+func buildAtomFeed(posts []blogPost, cfg feedConfig) (*atomFeed, error) {
+	updated := time.Time{}
+	entries := make([]atomEntry, 0, len(posts))
+	for _, bp := range posts {
+		t, err := time.Parse("2006-01-02", bp.dateStr)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse date %q for feed entry %q: %w", bp.dateStr, bp.frontMatter.slug, err)
+		}
+		if t.After(updated) {
+			updated = t
+		}
+		contentHTML, err := renderPost(&bp)
+		if err != nil {
+			return nil, fmt.Errorf("unable to render feed entry %q: %w", bp.frontMatter.slug, err)
+		}
+		permalink := postPermalink(cfg.BaseURL, bp.frontMatter.slug)
+		entries = append(entries, atomEntry{
+			Title:   bp.frontMatter.title,
+			ID:      permalink,
+			Updated: t.Format(time.RFC3339),
+			Link:    atomLink{Rel: "alternate", Href: permalink},
+			Summary: bp.excerpt,
+			Content: atomContent{Type: "html", Body: string(contentHTML)},
+		})
+	}
+	if updated.IsZero() {
+		updated = time.Unix(0, 0).UTC()
+	}
+
+	feed := &atomFeed{
+		Title:   cfg.Title,
+		ID:      cfg.BaseURL + "/",
+		Updated: updated.Format(time.RFC3339),
+		Links: []atomLink{
+			{Rel: "self", Href: cfg.BaseURL + "/atom.xml"},
+			{Rel: "alternate", Href: cfg.BaseURL + "/"},
+		},
+		Entries: entries,
+	}
+	feed.Author.Name = cfg.Author
+	return feed, nil
+}
+
+func buildRSSFeed(posts []blogPost, cfg feedConfig) (*rssFeed, error) {
+	items := make([]rssItem, 0, len(posts))
+	for _, bp := range posts {
+		t, err := time.Parse("2006-01-02", bp.dateStr)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse date %q for feed item %q: %w", bp.dateStr, bp.frontMatter.slug, err)
+		}
+		permalink := postPermalink(cfg.BaseURL, bp.frontMatter.slug)
+		items = append(items, rssItem{
+			Title:       bp.frontMatter.title,
+			Link:        permalink,
+			GUID:        permalink,
+			PubDate:     t.Format(time.RFC1123Z),
+			Description: bp.excerpt,
+		})
+	}
+	return &rssFeed{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:       cfg.Title,
+			Link:        cfg.BaseURL,
+			Description: cfg.Title,
+			Items:       items,
+		},
+	}, nil
+}
+
+func writeXMLFile(path string, v any) error {
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to marshal %s: %w", path, err)
+	}
+	out := append([]byte(xml.Header), data...)
+	return os.WriteFile(path, out, os.ModePerm)
+}
+
+// taxonomyPostSummary is the per-post entry listed on a tag's _index.md.
+type taxonomyPostSummary struct {
+	Title   string `toml:"title"`
+	Date    string `toml:"date"`
+	Slug    string `toml:"slug"`
+	Excerpt string `toml:"excerpt"`
+}
+
+type taxonomyIndexFrontMatter struct {
+	Title string                `toml:"title"`
+	Type  string                `toml:"type"`
+	Posts []taxonomyPostSummary `toml:"posts"`
+}
+
+// slugifyTag turns a tag name into a URL-safe slug: lowercased, with runs
+// of non-alphanumerics collapsed to a single "-".
+func slugifyTag(tag string) string {
+	lower := strings.ToLower(tag)
+	slug := regexp.MustCompile(`[^a-z0-9]+`).ReplaceAllString(lower, "-")
+	return strings.Trim(slug, "-")
+}
+
+// writeTaxonomyIndexes groups posts by tag and writes one
+// content/tags/<slug>/_index.md per tag, each listing its posts ordered by
+// date descending. Tags themselves are processed in alphabetical order so
+// repeated runs produce stable output.
+func writeTaxonomyIndexes(outDir string, posts []blogPost) error {
+	byTag := make(map[string][]blogPost)
+	for _, bp := range posts {
+		for _, tag := range bp.frontMatter.categories {
+			byTag[tag] = append(byTag[tag], bp)
+		}
+	}
+
+	tags := make([]string, 0, len(byTag))
+	for tag := range byTag {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	for _, tag := range tags {
+		tagPosts := byTag[tag]
+		sort.Slice(tagPosts, func(i, j int) bool {
+			if tagPosts[i].dateStr != tagPosts[j].dateStr {
+				return tagPosts[i].dateStr > tagPosts[j].dateStr
+			}
+			return tagPosts[i].frontMatter.slug < tagPosts[j].frontMatter.slug
+		})
+
+		summaries := make([]taxonomyPostSummary, 0, len(tagPosts))
+		for _, bp := range tagPosts {
+			summaries = append(summaries, taxonomyPostSummary{
+				Title:   bp.frontMatter.title,
+				Date:    bp.dateStr,
+				Slug:    bp.frontMatter.slug,
+				Excerpt: bp.excerpt,
+			})
+		}
+
+		tagDir := filepath.Join(outDir, "tags", slugifyTag(tag))
+		if err := os.MkdirAll(tagDir, os.ModePerm); err != nil {
+			return fmt.Errorf("unable to create tag dir %s: %w", tagDir, err)
+		}
+
+		var content bytes.Buffer
+		content.WriteString("+++\n")
+		fm := taxonomyIndexFrontMatter{Title: tag, Type: "taxonomy", Posts: summaries}
+		if err := toml.NewEncoder(&content).Encode(fm); err != nil {
+			return fmt.Errorf("unable to encode tag frontmatter for %s: %w", tag, err)
+		}
+		content.WriteString("+++\n")
+
+		if err := os.WriteFile(filepath.Join(tagDir, "_index.md"), content.Bytes(), os.ModePerm); err != nil {
+			return fmt.Errorf("unable to write tag index for %s: %w", tag, err)
+		}
+	}
+	return nil
+}
+
 func main() {
+	workers := flag.Int("workers", runtime.NumCPU(), "number of posts to process concurrently")
+	renderMode := flag.Bool("render", false, "render full static HTML pages instead of Hugo frontmatter files")
+	flag.Parse()
+
+	repoPath := "."
 	dirName := "_posts"
 	outDirName := "content"
 	entries, err := os.ReadDir(dirName)
 	if err != nil {
 		panic(err)
 	}
-	i := 1
-	for _, e := range entries {
-		filename := e.Name()
-		bp, err := process(filepath.Join(dirName, filename))
-		if err != nil {
-			panic(err)
+
+	datesCachePath := filepath.Join(outDirName, "dates.json")
+	datesCache, err := loadVCSDatesCache(datesCachePath)
+	if err != nil {
+		panic(err)
+	}
+	var datesMu sync.Mutex
+
+	posts, failures := processAll(entries, dirName, outDirName, repoPath, datesCache, &datesMu, *workers, *renderMode)
+
+	if err := saveVCSDatesCache(datesCachePath, datesCache); err != nil {
+		panic(err)
+	}
+
+	var feedPosts []blogPost
+	for _, bp := range posts {
+		if bp.frontMatter.postType == "post" {
+			feedPosts = append(feedPosts, bp)
 		}
-		err = writeOutBlogPost(outDirName, filename, &bp)
-		if err != nil {
+	}
+	feedCfg, err := loadFeedConfig(filepath.Join(repoPath, "feed.toml"))
+	switch {
+	case err == nil:
+		if err := writeFeeds(outDirName, feedPosts, feedCfg); err != nil {
 			panic(err)
 		}
-		i += 1
+	case errors.Is(err, fs.ErrNotExist):
+		fmt.Println("no feed.toml found, skipping feed generation")
+	default:
+		panic(err)
+	}
+
+	if err := writeTaxonomyIndexes(outDirName, posts); err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("processed %d posts, %d failed\n", len(posts), len(failures))
+	if len(failures) > 0 {
+		panic(errors.Join(failures...))
+	}
+}
+
+// processAll fans out process()+writeOutBlogPost() over workers goroutines,
+// one per post filename, collecting every successfully processed blogPost
+// and every failure instead of aborting on the first one.
+func processAll(entries []os.DirEntry, dirName, outDirName, repoPath string, datesCache vcsDatesCache, datesMu *sync.Mutex, workers int, renderMode bool) (posts []blogPost, failures []error) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	type result struct {
+		bp  blogPost
+		err error
+	}
+
+	filenames := make(chan string)
+	results := make(chan result)
+
+	claimed := claimedOutputDirs{}
+	var claimedMu sync.Mutex
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for filename := range filenames {
+				bp, err := process(repoPath, filepath.Join(dirName, filename), datesCache, datesMu)
+				if err == nil {
+					err = writeOutBlogPost(outDirName, filename, &bp, renderMode, claimed, &claimedMu)
+				}
+				if err != nil {
+					err = fmt.Errorf("%s: %w", filename, err)
+				}
+				results <- result{bp: bp, err: err}
+			}
+		}()
+	}
+
+	go func() {
+		for _, e := range entries {
+			filenames <- e.Name()
+		}
+		close(filenames)
+		wg.Wait()
+		close(results)
+	}()
+
+	for res := range results {
+		if res.err != nil {
+			failures = append(failures, res.err)
+			continue
+		}
+		posts = append(posts, res.bp)
 	}
+	return posts, failures
 }